@@ -0,0 +1,83 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/cache"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest"
+)
+
+// NewCmdCache describes the "skaffold cache" command group. It's added to
+// the root command via rootCmd.AddCommand(NewCmdCache()) alongside
+// skaffold's other subcommands; it doesn't declare its own root command or
+// SkaffoldOptions var, both of which already exist on the root command.
+func NewCmdCache() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Interact with skaffold's local caches",
+	}
+	cmd.AddCommand(NewCmdCachePrune())
+	return cmd
+}
+
+// NewCmdCachePrune describes "skaffold cache prune".
+func NewCmdCachePrune() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Remove cache entries past their maxAge or over their maxSizeBytes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return doCachePrune(cmd.OutOrStdout())
+		},
+	}
+}
+
+func doCachePrune(out io.Writer) error {
+	caches, err := cache.NewCaches(cachesConfig())
+	if err != nil {
+		return err
+	}
+
+	pruned, err := caches.PruneAll()
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "Pruned %d cache entries\n", pruned)
+	return err
+}
+
+// cachesConfig builds the named cache config this run should prune: the
+// user's `caches:` declarations from opts.Caches, with the legacy
+// --cache-file flag (if set) overriding the "artifacts" entry's location so
+// `skaffold cache prune` targets whatever dir/backend a run actually used.
+func cachesConfig() map[string]latest.CacheConfig {
+	cfg := make(map[string]latest.CacheConfig, len(opts.Caches)+1)
+	for name, c := range opts.Caches {
+		cfg[name] = c
+	}
+	if opts.CacheFile != "" {
+		cfg[cache.ArtifactsCacheName] = latest.CacheConfig{Dir: filepath.Dir(opts.CacheFile)}
+	}
+	return cfg
+}
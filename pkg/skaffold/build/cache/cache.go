@@ -0,0 +1,211 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build"
+	namedcache "github.com/GoogleContainerTools/skaffold/pkg/skaffold/cache"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest"
+)
+
+// indexKey is the single key the "artifacts" named cache is stored under.
+// It holds the entire cacheFileContents blob, the same read/modify/write
+// shape the old single-file cache used.
+const indexKey = "index"
+
+// ImageDetails holds the information about a cached artifact that's needed
+// to decide whether it can be reused and, if so, to locate its built image.
+type ImageDetails struct {
+	Digest string `yaml:"digest,omitempty"`
+	ID     string `yaml:"id,omitempty"`
+
+	// FileHashes records the hash of each dependency that went into Digest,
+	// keyed by path, so a later rebuild decision can report exactly which
+	// files changed instead of just that "the hash changed".
+	FileHashes map[string]string `yaml:"fileHashes,omitempty"`
+
+	// HasherName is the Hasher.Name() that computed FileHashes and Digest's
+	// key, so that switching algorithms invalidates this entry explicitly
+	// instead of risking a silent collision between two incompatible
+	// digests. The name does not include the hash salt: a salt change
+	// invalidates entries only by changing the key they are looked up by.
+	HasherName string `yaml:"hasher,omitempty"`
+}
+
+// ArtifactCache is a local, on-disk record of hash -> built image, keyed by
+// the hash computed in getHashForArtifact.
+type ArtifactCache map[string]ImageDetails
+
+// cacheFileContents is the format the "artifacts" named cache entry is
+// stored in. DepGraphs is kept separate from Artifacts because it's
+// indexed by artifact image name rather than by hash, so that a rebuild
+// decision can always find the previous per-file hashes for an artifact
+// even though its overall hash has just changed.
+type cacheFileContents struct {
+	Artifacts ArtifactCache                `json:"artifacts"`
+	DepGraphs map[string]map[string]string `json:"depGraphs,omitempty"`
+}
+
+// Cache holds any state needed to look up and store cached artifacts.
+type Cache struct {
+	artifactCache ArtifactCache
+	depGraphs     map[string]map[string]string
+	namedCache    *namedcache.Cache
+	unlock        func()
+	client        docker.LocalDaemon
+	builder       build.Builder
+	useCache      bool
+	needsPush     bool
+
+	remoteCache bool
+	remoteRepo  string
+}
+
+// NewCache returns the cache to be used for a given run. If the user hasn't
+// opted into caching, it returns a no-op Cache whose RetrieveCachedArtifacts
+// and CacheArtifacts methods are effectively a pass-through.
+//
+// Storage is delegated to the "artifacts" entry of the named cache
+// registry (see pkg/skaffold/cache), so that by default - i.e. without the
+// user declaring any `caches:` in skaffold.yaml - behavior is unchanged
+// from the single on-disk JSON file this package used to manage directly.
+func NewCache(builder build.Builder, opts *config.SkaffoldOptions, needsPush bool) *Cache {
+	if !opts.CacheArtifacts {
+		return &Cache{}
+	}
+
+	hasher, err := NewHasher(opts.CacheHashAlgorithm, opts.CacheHashSalt)
+	if err != nil {
+		logrus.Warnf("Error configuring cache hasher, not using skaffold cache: %v", err)
+		return &Cache{}
+	}
+	SetHasher(hasher)
+
+	cfg := artifactsCacheConfig(opts)
+	caches, err := namedcache.NewCaches(map[string]latest.CacheConfig{
+		namedcache.ArtifactsCacheName: cfg,
+	})
+	if err != nil {
+		logrus.Warnf("Error configuring skaffold cache, not using it: %v", err)
+		return &Cache{}
+	}
+
+	artifacts, err := caches.Get(namedcache.ArtifactsCacheName)
+	if err != nil {
+		logrus.Warnf("Error configuring skaffold cache, not using it: %v", err)
+		return &Cache{}
+	}
+
+	// Hold the lock across the whole read-modify-write cycle of this run -
+	// from the read below through the write in save() - so that concurrent
+	// skaffold build invocations don't clobber each other's cache updates.
+	unlock, err := artifacts.Lock(indexKey)
+	if err != nil {
+		logrus.Warnf("Error locking artifact cache, not using skaffold cache: %v", err)
+		return &Cache{}
+	}
+
+	contents, err := retrieveCacheFileContents(artifacts)
+	if err != nil {
+		unlock()
+		logrus.Warnf("Error retrieving artifact cache, not using skaffold cache: %v", err)
+		return &Cache{}
+	}
+
+	client, err := docker.NewAPIClient(opts.InsecureRegistries)
+	if err != nil {
+		unlock()
+		logrus.Warnf("Error retrieving local daemon client, not using skaffold cache: %v", err)
+		return &Cache{}
+	}
+
+	return &Cache{
+		artifactCache: contents.Artifacts,
+		depGraphs:     contents.DepGraphs,
+		namedCache:    artifacts,
+		unlock:        unlock,
+		useCache:      opts.CacheArtifacts,
+		client:        client,
+		builder:       builder,
+		needsPush:     needsPush,
+		remoteCache:   opts.RemoteCache,
+		remoteRepo:    opts.RemoteCacheRepo,
+	}
+}
+
+// artifactsCacheConfig builds the CacheConfig for the "artifacts" cache:
+// the user's "artifacts" entry from opts.Caches, if any, with the legacy
+// --cache-file flag (if set) overriding it with a disk location so
+// existing workflows keep working unchanged.
+func artifactsCacheConfig(opts *config.SkaffoldOptions) latest.CacheConfig {
+	if opts.CacheFile == "" {
+		return opts.Caches[namedcache.ArtifactsCacheName]
+	}
+	return latest.CacheConfig{Dir: filepath.Dir(opts.CacheFile)}
+}
+
+// retrieveCacheFileContents reads the "artifacts" cache's index entry,
+// returning an empty cache if it doesn't exist yet.
+func retrieveCacheFileContents(artifacts *namedcache.Cache) (cacheFileContents, error) {
+	contents := cacheFileContents{Artifacts: ArtifactCache{}}
+
+	raw, found, err := artifacts.Get(indexKey)
+	if err != nil {
+		return contents, err
+	}
+	if !found {
+		contents.DepGraphs = map[string]map[string]string{}
+		return contents, nil
+	}
+
+	if err := json.Unmarshal(raw, &contents); err != nil {
+		return contents, err
+	}
+	if contents.Artifacts == nil {
+		contents.Artifacts = ArtifactCache{}
+	}
+	if contents.DepGraphs == nil {
+		contents.DepGraphs = map[string]map[string]string{}
+	}
+	return contents, nil
+}
+
+// save writes the in-memory artifact cache back out to the "artifacts"
+// named cache and releases the lock taken in NewCache, ending the
+// read-modify-write cycle for this run.
+func (c *Cache) save() error {
+	if c.unlock != nil {
+		defer c.unlock()
+	}
+
+	data, err := json.MarshalIndent(cacheFileContents{
+		Artifacts: c.artifactCache,
+		DepGraphs: c.depGraphs,
+	}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling cache")
+	}
+	return c.namedCache.Put(indexKey, data)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeSet describes, for a single artifact, how its dependencies moved
+// between two cache checks.
+type ChangeSet struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+	Reason   string
+}
+
+// Empty reports whether the two file maps compared equal, i.e. nothing
+// triggered a rebuild.
+func (c ChangeSet) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// diffFileHashes compares the per-dependency hash map from a previous cache
+// entry against the one just computed, and summarizes what changed.
+func diffFileHashes(old, new map[string]string) ChangeSet {
+	var cs ChangeSet
+
+	for path, newHash := range new {
+		oldHash, existed := old[path]
+		switch {
+		case !existed:
+			cs.Added = append(cs.Added, path)
+		case oldHash != newHash:
+			cs.Modified = append(cs.Modified, path)
+		}
+	}
+	for path := range old {
+		if _, stillExists := new[path]; !stillExists {
+			cs.Removed = append(cs.Removed, path)
+		}
+	}
+
+	sort.Strings(cs.Added)
+	sort.Strings(cs.Removed)
+	sort.Strings(cs.Modified)
+	cs.Reason = cs.describe()
+	return cs
+}
+
+// describe renders a short, human-readable explanation of the change set,
+// e.g. "src/foo.go changed" or "2 files changed: src/a.go, src/b.go".
+func (c ChangeSet) describe() string {
+	if c.Empty() {
+		return ""
+	}
+
+	changed := append(append(append([]string{}, c.Added...), c.Removed...), c.Modified...)
+	if len(changed) == 1 {
+		return fmt.Sprintf("%s changed", changed[0])
+	}
+	return fmt.Sprintf("%d files changed: %s", len(changed), strings.Join(changed, ", "))
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestDiffFileHashes(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      map[string]string
+		new      map[string]string
+		expected ChangeSet
+	}{
+		{
+			name:     "nothing changed",
+			old:      map[string]string{"a": "1"},
+			new:      map[string]string{"a": "1"},
+			expected: ChangeSet{},
+		},
+		{
+			name:     "file added",
+			old:      map[string]string{},
+			new:      map[string]string{"a": "1"},
+			expected: ChangeSet{Added: []string{"a"}, Reason: "a changed"},
+		},
+		{
+			name:     "file removed",
+			old:      map[string]string{"a": "1"},
+			new:      map[string]string{},
+			expected: ChangeSet{Removed: []string{"a"}, Reason: "a changed"},
+		},
+		{
+			name:     "file modified",
+			old:      map[string]string{"a": "1"},
+			new:      map[string]string{"a": "2"},
+			expected: ChangeSet{Modified: []string{"a"}, Reason: "a changed"},
+		},
+		{
+			name:     "multiple changes",
+			old:      map[string]string{"a": "1", "b": "1"},
+			new:      map[string]string{"a": "2", "c": "1"},
+			expected: ChangeSet{Added: []string{"c"}, Removed: []string{"b"}, Modified: []string{"a"}, Reason: "3 files changed: c, b, a"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := diffFileHashes(test.old, test.new)
+			testutil.CheckDeepEqual(t, test.expected, actual)
+		})
+	}
+}
+
+func TestChangeSetEmpty(t *testing.T) {
+	if !(ChangeSet{}).Empty() {
+		t.Error("expected zero-value ChangeSet to be Empty")
+	}
+	if (ChangeSet{Added: []string{"a"}}).Empty() {
+		t.Error("expected ChangeSet with an added file to not be Empty")
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest"
+)
+
+// getHashForArtifact computes a single hash representing the combined
+// contents of all of an artifact's dependencies, regardless of the order
+// they're returned in.
+func getHashForArtifact(ctx context.Context, builder build.Builder, a *latest.Artifact) (string, error) {
+	fileHashes, deps, err := getFileHashesForArtifact(ctx, builder, a)
+	if err != nil {
+		return "", err
+	}
+	return hashFromFileHashes(fileHashes, deps)
+}
+
+// hashFromFileHashes combines a path -> hash map into a single digest,
+// walking deps (which must already be sorted) so the result doesn't depend
+// on map iteration order.
+func hashFromFileHashes(fileHashes map[string]string, deps []string) (string, error) {
+	hashes := make([]string, 0, len(deps))
+	for _, d := range deps {
+		hashes = append(hashes, fileHashes[d])
+	}
+
+	hasher := sha256.New()
+	enc := json.NewEncoder(hasher)
+	if err := enc.Encode(hashes); err != nil {
+		return "", errors.Wrap(err, "encoding hash")
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// getFileHashesForArtifact hashes each of an artifact's dependencies
+// individually, returning the path -> hash map alongside the sorted list
+// of paths it was built from.
+func getFileHashesForArtifact(ctx context.Context, builder build.Builder, a *latest.Artifact) (map[string]string, []string, error) {
+	deps, err := builder.DependenciesForArtifact(ctx, a)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "getting dependencies for artifact")
+	}
+	sort.Strings(deps)
+
+	fileHashes := make(map[string]string, len(deps))
+	for _, d := range deps {
+		h, err := hashFunction(d)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "getting hash for %s", d)
+		}
+		fileHashes[d] = h
+	}
+	return fileHashes, deps, nil
+}
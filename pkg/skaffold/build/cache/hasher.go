@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/pkg/errors"
+)
+
+// Hasher is a strategy for hashing a single dependency. Its Name is
+// persisted alongside each cache entry so that switching hashers causes a
+// controlled, logged invalidation instead of silently mixing digests from
+// two different algorithms.
+type Hasher interface {
+	Hash(dep string) (string, error)
+	Name() string
+}
+
+// NewHasher returns the Hasher named by name (defaulting to sha256),
+// mixing salt into every hash it computes.
+func NewHasher(name, salt string) (Hasher, error) {
+	switch name {
+	case "", "sha256":
+		return fileHasher{newHash: sha256.New, name: "sha256", salt: salt}, nil
+	case "sha512":
+		return fileHasher{newHash: sha512.New, name: "sha512", salt: salt}, nil
+	case "xxhash":
+		return fileHasher{newHash: func() hash.Hash { return xxhash.New() }, name: "xxhash", salt: salt}, nil
+	default:
+		return nil, errors.Errorf("unknown cache hash algorithm %q", name)
+	}
+}
+
+// fileHasher implements Hasher for any hash.Hash constructor.
+type fileHasher struct {
+	newHash func() hash.Hash
+	name    string
+	salt    string
+}
+
+func (f fileHasher) Name() string { return f.name }
+
+// Hash returns the hash of the configured salt and p's base name combined
+// with, for a symlink, its target path, or otherwise its file mode and
+// contents.
+func (f fileHasher) Hash(p string) (string, error) {
+	fi, err := os.Lstat(p)
+	if err != nil {
+		return "", err
+	}
+
+	h := f.newHash()
+	if f.salt != "" {
+		h.Write([]byte(f.salt))
+	}
+	h.Write([]byte(fi.Name()))
+
+	if fi.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(p)
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(target))
+	} else {
+		h.Write([]byte(fi.Mode().String()))
+
+		file, err := os.Open(p)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+
+		if _, err := io.Copy(h, file); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// activeHasher is the Hasher used for the current run. It defaults to
+// unsalted sha256, preserving today's behavior.
+var activeHasher Hasher = fileHasher{newHash: sha256.New, name: "sha256"}
+
+// hashFunction hashes the contents of a single dependency using
+// activeHasher. It's a var, rather than a direct call to
+// activeHasher.Hash, so it can be swapped out in tests.
+var hashFunction = activeHasher.Hash
+
+// SetHasher configures the Hasher used for subsequent cache lookups in
+// this process.
+func SetHasher(h Hasher) {
+	activeHasher = h
+	hashFunction = h.Hash
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestNewHasher(t *testing.T) {
+	tests := []struct {
+		name         string
+		algorithm    string
+		expectedName string
+		shouldErr    bool
+	}{
+		{name: "default", algorithm: "", expectedName: "sha256"},
+		{name: "sha256", algorithm: "sha256", expectedName: "sha256"},
+		{name: "sha512", algorithm: "sha512", expectedName: "sha512"},
+		{name: "xxhash", algorithm: "xxhash", expectedName: "xxhash"},
+		{name: "unknown algorithm", algorithm: "md5", shouldErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h, err := NewHasher(test.algorithm, "")
+			testutil.CheckError(t, test.shouldErr, err)
+			if !test.shouldErr {
+				testutil.CheckDeepEqual(t, test.expectedName, h.Name())
+			}
+		})
+	}
+}
+
+func TestFileHasherSalt(t *testing.T) {
+	folder, cleanup := testutil.NewTempDir(t)
+	defer cleanup()
+	folder.Write("foo", "contents")
+
+	unsalted, err := NewHasher("sha256", "")
+	testutil.CheckError(t, false, err)
+	salted, err := NewHasher("sha256", "pepper")
+	testutil.CheckError(t, false, err)
+
+	unsaltedHash, err := unsalted.Hash(folder.Path("foo"))
+	testutil.CheckError(t, false, err)
+	saltedHash, err := salted.Hash(folder.Path("foo"))
+	testutil.CheckError(t, false, err)
+
+	if unsaltedHash == saltedHash {
+		t.Fatalf("expected salted and unsalted hashes to differ, both were %s", unsaltedHash)
+	}
+}
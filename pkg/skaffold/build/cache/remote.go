@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+)
+
+// cacheManifestAnnotation is the image config label used to store the
+// digest of the image a remote cache manifest describes.
+const cacheManifestAnnotation = "dev.skaffold.cache.digest"
+
+// remoteCacheImage is the name of the zero-layer image skaffold pushes to
+// the remote cache repo, tagged with the artifact hash.
+func remoteCacheImage(repo, hash string) (name.Tag, error) {
+	return name.NewTag(fmt.Sprintf("%s:%s", repo, hash), name.WeakValidation)
+}
+
+// lookupRemoteCache fetches the cache manifest for hash in repo and, if it
+// exists, pulls the image digest it references into the local daemon and
+// tags it as imageName. The returned ImageDetails is only ever for an
+// image that's now actually present locally - a caller must not report a
+// hit without doing this, or the next pipeline stage won't find it.
+func lookupRemoteCache(ctx context.Context, client docker.LocalDaemon, imageName, repo, hash string) (ImageDetails, error) {
+	digest, err := resolveRemoteCacheDigest(repo, hash)
+	if err != nil {
+		return ImageDetails{}, err
+	}
+
+	builtRef := fmt.Sprintf("%s@%s", repo, digest)
+	if err := client.Pull(ctx, ioutil.Discard, builtRef); err != nil {
+		return ImageDetails{}, errors.Wrapf(err, "pulling cached image %s", builtRef)
+	}
+	if err := client.Tag(ctx, builtRef, imageName); err != nil {
+		return ImageDetails{}, errors.Wrapf(err, "tagging cached image %s as %s", builtRef, imageName)
+	}
+
+	id, err := client.ImageID(ctx, imageName)
+	if err != nil {
+		return ImageDetails{}, errors.Wrapf(err, "getting image ID for %s", imageName)
+	}
+
+	return ImageDetails{Digest: digest, ID: id}, nil
+}
+
+// resolveRemoteCacheDigest fetches the cache manifest for hash in repo and
+// its config, and returns the build digest recorded in its label.
+func resolveRemoteCacheDigest(repo, hash string) (string, error) {
+	if repo == "" {
+		return "", errors.New("no remote cache repository configured")
+	}
+
+	ref, err := remoteCacheImage(repo, hash)
+	if err != nil {
+		return "", errors.Wrap(err, "building cache image reference")
+	}
+
+	img, err := remote.Image(ref, remote.WithAuthFromKeychain(docker.Keychain))
+	if err != nil {
+		return "", errors.Wrap(err, "fetching cache manifest")
+	}
+
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return "", errors.Wrap(err, "reading cache manifest config")
+	}
+
+	digest, ok := cfg.Config.Labels[cacheManifestAnnotation]
+	if !ok {
+		return "", errors.Errorf("cache manifest %s missing %s annotation", ref, cacheManifestAnnotation)
+	}
+	return digest, nil
+}
+
+// pushRemoteCache pushes a small cache manifest to repo, tagged with hash
+// and annotated with builtDigest, the digest of the build image that was
+// just pushed to repo under its own tag - callers must push that image to
+// repo before calling this, or the annotation will reference a digest that
+// was never actually uploaded and can't be pulled back.
+func pushRemoteCache(ctx context.Context, repo, hash, builtDigest string) error {
+	ref, err := remoteCacheImage(repo, hash)
+	if err != nil {
+		return errors.Wrap(err, "building cache image reference")
+	}
+
+	img, err := mutate.Config(empty.Image, v1.Config{
+		Labels: map[string]string{cacheManifestAnnotation: builtDigest},
+	})
+	if err != nil {
+		return errors.Wrap(err, "building cache manifest")
+	}
+
+	if err := remote.Write(ref, img, remote.WithAuthFromKeychain(docker.Keychain)); err != nil {
+		return errors.Wrap(err, "pushing cache manifest")
+	}
+	return nil
+}
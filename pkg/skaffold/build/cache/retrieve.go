@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"io"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/event"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest"
+)
+
+// RetrieveCachedArtifacts checks, for each artifact, whether a build can be
+// skipped because a matching result is already cached. Artifacts that
+// aren't cached (or whose cache lookup fails) are returned for building.
+func (c *Cache) RetrieveCachedArtifacts(ctx context.Context, out io.Writer, artifacts []*latest.Artifact) ([]*latest.Artifact, []build.Artifact) {
+	if !c.useCache {
+		return artifacts, nil
+	}
+
+	var needToBuild []*latest.Artifact
+	var built []build.Artifact
+
+	for _, a := range artifacts {
+		fileHashes, deps, err := getFileHashesForArtifact(ctx, c.builder, a)
+		if err != nil {
+			logrus.Debugf("Error getting hash for artifact %s, skipping cache: %v", a.ImageName, err)
+			needToBuild = append(needToBuild, a)
+			continue
+		}
+
+		hash, err := hashFromFileHashes(fileHashes, deps)
+		if err != nil {
+			logrus.Debugf("Error hashing dependencies for artifact %s, skipping cache: %v", a.ImageName, err)
+			needToBuild = append(needToBuild, a)
+			continue
+		}
+
+		if details, found := c.lookup(ctx, a, hash); found {
+			c.depGraphs[a.ImageName] = fileHashes
+			built = append(built, build.Artifact{
+				ImageName: a.ImageName,
+				Tag:       details.ID,
+			})
+			continue
+		}
+
+		cs := diffFileHashes(c.depGraphs[a.ImageName], fileHashes)
+		if cs.Reason != "" {
+			event.ArtifactRebuildReason(a.ImageName, cs.Reason)
+		}
+		c.depGraphs[a.ImageName] = fileHashes
+
+		needToBuild = append(needToBuild, a)
+	}
+
+	return needToBuild, built
+}
+
+// lookup checks the local cache first, and, if the artifact isn't found
+// there and remote caching is enabled, falls back to the configured OCI
+// registry.
+func (c *Cache) lookup(ctx context.Context, a *latest.Artifact, hash string) (ImageDetails, bool) {
+	if details, found := c.artifactCache[hash]; found {
+		if details.HasherName != "" && details.HasherName != activeHasher.Name() {
+			logrus.Debugf("Cache entry for %s was hashed with %s, not %s: invalidating", a.ImageName, details.HasherName, activeHasher.Name())
+		} else if _, err := c.client.ImageID(ctx, details.Digest); err == nil {
+			return details, true
+		}
+	}
+
+	if !c.remoteCache {
+		return ImageDetails{}, false
+	}
+
+	details, err := lookupRemoteCache(ctx, c.client, a.ImageName, c.remoteRepo, hash)
+	if err != nil {
+		logrus.Debugf("Remote cache lookup failed for %s: %v", a.ImageName, err)
+		return ImageDetails{}, false
+	}
+	details.HasherName = activeHasher.Name()
+
+	// Record the remote hit locally so the next lookup stays on disk.
+	c.artifactCache[hash] = details
+	return details, true
+}
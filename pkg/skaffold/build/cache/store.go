@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest"
+)
+
+// CacheArtifacts records the results of a build that just completed so a
+// future run of RetrieveCachedArtifacts can find them: the local cache
+// entry is updated for each built artifact that can be hashed, and, if
+// remote caching is enabled and this run pushes its images (needsPush), the
+// build is also pushed to the configured OCI cache repo so other machines
+// can reuse it.
+func (c *Cache) CacheArtifacts(ctx context.Context, artifacts []*latest.Artifact, built []build.Artifact) error {
+	if !c.useCache {
+		return nil
+	}
+
+	builtByName := make(map[string]build.Artifact, len(built))
+	for _, b := range built {
+		builtByName[b.ImageName] = b
+	}
+
+	for _, a := range artifacts {
+		b, ok := builtByName[a.ImageName]
+		if !ok {
+			continue
+		}
+
+		fileHashes, deps, err := getFileHashesForArtifact(ctx, c.builder, a)
+		if err != nil {
+			logrus.Debugf("Error hashing dependencies for %s, not caching it: %v", a.ImageName, err)
+			continue
+		}
+		hash, err := hashFromFileHashes(fileHashes, deps)
+		if err != nil {
+			logrus.Debugf("Error hashing dependencies for %s, not caching it: %v", a.ImageName, err)
+			continue
+		}
+
+		id, err := c.client.ImageID(ctx, b.Tag)
+		if err != nil {
+			logrus.Debugf("Error getting image ID for %s, not caching it: %v", a.ImageName, err)
+			continue
+		}
+
+		c.artifactCache[hash] = ImageDetails{
+			Digest:     id,
+			ID:         id,
+			FileHashes: fileHashes,
+			HasherName: activeHasher.Name(),
+		}
+		c.depGraphs[a.ImageName] = fileHashes
+
+		if c.remoteCache && c.needsPush {
+			if err := c.pushBuildToRemoteCache(ctx, b.Tag, hash); err != nil {
+				logrus.Warnf("Error pushing %s to remote cache: %v", a.ImageName, err)
+			}
+		}
+	}
+
+	return c.save()
+}
+
+// pushBuildToRemoteCache pushes the image tagged builtTag to c.remoteRepo
+// and writes a cache manifest annotated with the digest that push produced,
+// so a lookupRemoteCache on another machine pulls a digest that's actually
+// present in the repo - not the purely-local image ID. Callers only reach
+// this when c.needsPush is set, since a local-only run (e.g. building
+// straight into a local cluster's daemon) has no reason to have push
+// access to remoteRepo configured.
+func (c *Cache) pushBuildToRemoteCache(ctx context.Context, builtTag, hash string) error {
+	buildRef := fmt.Sprintf("%s:%s-build", c.remoteRepo, hash)
+	if err := c.client.Tag(ctx, builtTag, buildRef); err != nil {
+		return errors.Wrapf(err, "tagging %s as %s", builtTag, buildRef)
+	}
+
+	digest, err := c.client.Push(ctx, ioutil.Discard, buildRef)
+	if err != nil {
+		return errors.Wrapf(err, "pushing %s", buildRef)
+	}
+
+	return pushRemoteCache(ctx, c.remoteRepo, hash, digest)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package local parses `docker run`-style container option passthrough
+// strings into the container.Config/container.HostConfig fragments a
+// builder would merge into the container it creates for a build.
+//
+// Neither the local builder itself nor latest.Artifact exist in this
+// checkout, so parseContainerOptions/merge have no call site yet and stay
+// unexported rather than pretend to be a usable public API: calling them
+// today is a parse-and-discard no-op, and latest.Artifact has no
+// BuildOptions field to parse a per-artifact override from. Wiring this
+// into an actual build (SkaffoldOptions.BuildContainerOptions as the
+// default, merged with a new latest.Artifact.BuildOptions override) is
+// follow-up work, not part of this change.
+package local
+
+import (
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/google/shlex"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// ContainerOptions are the container.Config/container.HostConfig fragments
+// parsed out of a raw option string. They're merged onto whatever the
+// builder would otherwise produce, rather than replacing it, so passing
+// one option doesn't require also repeating everything else.
+type ContainerOptions struct {
+	Platform   string
+	Config     container.Config
+	HostConfig container.HostConfig
+}
+
+// parseContainerOptions parses raw with a flag set of its own that accepts
+// --network, --platform, --cpus, --security-opt, --add-host and
+// -v/--volume, named as `docker run` names them. Any other flag is an
+// error, and the --security-opt, --add-host and --volume values are kept
+// as given, without the docker CLI's own validation. Unexported: it has
+// no caller outside this package yet (see the package doc), and whatever
+// eventually calls it will live in this same package.
+func parseContainerOptions(raw string) (ContainerOptions, error) {
+	var opts ContainerOptions
+	if strings.TrimSpace(raw) == "" {
+		return opts, nil
+	}
+
+	args, err := shlex.Split(raw)
+	if err != nil {
+		return opts, errors.Wrap(err, "splitting container options")
+	}
+
+	var (
+		network     string
+		platform    string
+		cpus        float64
+		securityOpt []string
+		addHost     []string
+		volumes     []string
+	)
+
+	flags := pflag.NewFlagSet("container-options", pflag.ContinueOnError)
+	flags.StringVar(&network, "network", "", "")
+	flags.StringVar(&platform, "platform", "", "")
+	flags.Float64Var(&cpus, "cpus", 0, "")
+	flags.StringArrayVar(&securityOpt, "security-opt", nil, "")
+	flags.StringArrayVar(&addHost, "add-host", nil, "")
+	flags.StringArrayVarP(&volumes, "volume", "v", nil, "")
+
+	if err := flags.Parse(args); err != nil {
+		return opts, errors.Wrap(err, "parsing container options")
+	}
+
+	opts.Platform = platform
+	opts.HostConfig.NetworkMode = container.NetworkMode(network)
+	opts.HostConfig.SecurityOpt = securityOpt
+	opts.HostConfig.ExtraHosts = addHost
+	opts.HostConfig.Binds = volumes
+	if cpus > 0 {
+		opts.HostConfig.Resources.NanoCPUs = int64(cpus * 1e9)
+	}
+
+	return opts, nil
+}
+
+// merge overlays override onto base, with override's non-zero values for
+// the fields parseContainerOptions sets winning; Config is taken from base.
+// It has no caller yet (see the package doc); its override/base pair fits
+// an artifact-level option string over the global
+// SkaffoldOptions.BuildContainerOptions default.
+func merge(base, override ContainerOptions) ContainerOptions {
+	merged := base
+	if override.Platform != "" {
+		merged.Platform = override.Platform
+	}
+	if override.HostConfig.NetworkMode != "" {
+		merged.HostConfig.NetworkMode = override.HostConfig.NetworkMode
+	}
+	if len(override.HostConfig.SecurityOpt) > 0 {
+		merged.HostConfig.SecurityOpt = override.HostConfig.SecurityOpt
+	}
+	if len(override.HostConfig.ExtraHosts) > 0 {
+		merged.HostConfig.ExtraHosts = override.HostConfig.ExtraHosts
+	}
+	if len(override.HostConfig.Binds) > 0 {
+		merged.HostConfig.Binds = override.HostConfig.Binds
+	}
+	if override.HostConfig.Resources.NanoCPUs != 0 {
+		merged.HostConfig.Resources.NanoCPUs = override.HostConfig.Resources.NanoCPUs
+	}
+	return merged
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestParseContainerOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected ContainerOptions
+	}{
+		{
+			name:     "empty",
+			raw:      "",
+			expected: ContainerOptions{},
+		},
+		{
+			name: "network and platform",
+			raw:  "--network host --platform linux/arm64",
+			expected: ContainerOptions{
+				Platform:   "linux/arm64",
+				HostConfig: container.HostConfig{NetworkMode: "host"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts, err := parseContainerOptions(test.raw)
+			testutil.CheckError(t, false, err)
+			testutil.CheckDeepEqual(t, test.expected.Platform, opts.Platform)
+			testutil.CheckDeepEqual(t, test.expected.HostConfig.NetworkMode, opts.HostConfig.NetworkMode)
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := ContainerOptions{Platform: "linux/amd64"}
+	override := ContainerOptions{Platform: "linux/arm64"}
+
+	merged := merge(base, override)
+
+	testutil.CheckDeepEqual(t, "linux/arm64", merged.Platform)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cache provides a general-purpose, named-and-bounded cache
+// registry, loosely modeled on Hugo's filecache subsystem. Unlike
+// pkg/skaffold/build/cache, which only ever reasoned about build
+// artifacts, this package backs any number of independently configured
+// caches (artifacts, dependency resolution, test results, ...), each with
+// its own storage backend, TTL and size bound.
+package cache
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest"
+)
+
+// Entry is a single cached value together with the metadata needed to
+// decide whether it's still valid.
+type Entry struct {
+	Key      string
+	Value    []byte
+	StoredAt time.Time
+}
+
+// Backend is the storage strategy for a single named cache. It isn't
+// responsible for age-based expiry on its own: Cache.get compares
+// Entry.StoredAt against the configured maxAge on every read, and Prune
+// below additionally evicts expired/over-budget entries from storage.
+type Backend interface {
+	// Get returns the entry for key, or found=false if it doesn't exist.
+	Get(key string) (Entry, bool, error)
+
+	// Put stores value under key.
+	Put(key string, value []byte) error
+
+	// Prune evicts entries older than maxAge (zero means no age-based
+	// eviction) and, if the backend's total size exceeds maxSizeBytes
+	// (zero means unbounded), evicts the oldest entries until it doesn't.
+	// It returns the number of entries evicted.
+	Prune(maxAge time.Duration, maxSizeBytes int64) (int, error)
+
+	// LockID identifies the actual storage location a key is locked
+	// against (e.g. the resolved disk dir or OCI repo), so that two
+	// differently-configured caches never share a lock just because they
+	// happen to have the same logical name.
+	LockID() string
+}
+
+// Cache is a single named cache backed by one Backend.
+type Cache struct {
+	id      string
+	backend Backend
+	maxAge  time.Duration
+	maxSize int64
+}
+
+// GetOrCreate returns the cached value for key, calling create and storing
+// its result if there isn't one (or it has expired).
+func (c *Cache) GetOrCreate(key string, create func() ([]byte, error)) ([]byte, error) {
+	unlock, err := lockKey(c.backend.LockID(), key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "locking cache key %s/%s", c.id, key)
+	}
+	defer unlock()
+
+	if entry, found, err := c.get(key); err != nil {
+		return nil, errors.Wrapf(err, "reading cache key %s/%s", c.id, key)
+	} else if found {
+		return entry.Value, nil
+	}
+
+	value, err := create()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.backend.Put(key, value); err != nil {
+		return nil, errors.Wrapf(err, "storing cache key %s/%s", c.id, key)
+	}
+	return value, nil
+}
+
+// Get returns the raw value stored under key, without running a create
+// function on a miss. Useful for callers migrating an existing read/modify/
+// write file format onto a named cache.
+func (c *Cache) Get(key string) ([]byte, bool, error) {
+	entry, found, err := c.get(key)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	return entry.Value, true, nil
+}
+
+// get reads key from the backend and applies this cache's maxAge, so
+// GetOrCreate and Get both honor the same expiry the backend's own Prune
+// method would have evicted on.
+func (c *Cache) get(key string) (Entry, bool, error) {
+	entry, found, err := c.backend.Get(key)
+	if err != nil || !found {
+		return entry, found, err
+	}
+	if c.maxAge > 0 && time.Since(entry.StoredAt) > c.maxAge {
+		return Entry{}, false, nil
+	}
+	return entry, true, nil
+}
+
+// Put stores value under key unconditionally.
+func (c *Cache) Put(key string, value []byte) error {
+	return c.backend.Put(key, value)
+}
+
+// Lock takes an exclusive, cross-process lock on this cache's key and
+// returns a function to release it. It's meant for callers, like
+// pkg/skaffold/build/cache, that need to hold a lock across a longer
+// read-modify-write cycle than a single GetOrCreate call covers.
+func (c *Cache) Lock(key string) (func(), error) {
+	return lockKey(c.backend.LockID(), key)
+}
+
+// Prune evicts expired and over-budget entries from this cache.
+func (c *Cache) Prune() (int, error) {
+	return c.backend.Prune(c.maxAge, c.maxSize)
+}
+
+// newBackend builds the Backend configured for cfg.
+func newBackend(name string, cfg latest.CacheConfig) (Backend, error) {
+	switch cfg.Backend {
+	case "", "disk":
+		dir := cfg.Dir
+		if dir == "" {
+			d, err := defaultCacheDir(name)
+			if err != nil {
+				return nil, err
+			}
+			dir = d
+		}
+		return newDiskBackend(dir)
+	case "memory":
+		return newMemoryBackend(), nil
+	case "oci":
+		if cfg.Repo == "" {
+			return nil, errors.Errorf("cache %q: oci backend requires repo", name)
+		}
+		return newOCIBackend(cfg.Repo), nil
+	default:
+		return nil, errors.Errorf("cache %q: unknown backend %q", name, cfg.Backend)
+	}
+}
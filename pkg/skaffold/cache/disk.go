@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// diskBackend stores entries as plain files under dir, named by the sha256
+// of their key to keep arbitrary keys filesystem-safe.
+type diskBackend struct {
+	dir string
+}
+
+func newDiskBackend(dir string) (Backend, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "creating cache dir %s", dir)
+	}
+	return &diskBackend{dir: dir}, nil
+}
+
+// LockID is the resolved cache dir, so two disk backends pointed at
+// different dirs (e.g. via distinct --cache-file flags) never share a
+// lock just because they're both named "artifacts".
+func (d *diskBackend) LockID() string {
+	return "disk:" + d.dir
+}
+
+func (d *diskBackend) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+}
+
+func (d *diskBackend) Get(key string) (Entry, bool, error) {
+	p := d.path(key)
+	fi, err := os.Stat(p)
+	if os.IsNotExist(err) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	value, err := ioutil.ReadFile(p)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	return Entry{Key: key, Value: value, StoredAt: fi.ModTime()}, true, nil
+}
+
+func (d *diskBackend) Put(key string, value []byte) error {
+	return ioutil.WriteFile(d.path(key), value, 0644)
+}
+
+func (d *diskBackend) Prune(maxAge time.Duration, maxSizeBytes int64) (int, error) {
+	entries, err := ioutil.ReadDir(d.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var pruned int
+	now := time.Now()
+	kept := entries[:0]
+	for _, fi := range entries {
+		if maxAge > 0 && now.Sub(fi.ModTime()) > maxAge {
+			if err := os.Remove(filepath.Join(d.dir, fi.Name())); err != nil {
+				return pruned, err
+			}
+			pruned++
+			continue
+		}
+		kept = append(kept, fi)
+	}
+
+	if maxSizeBytes > 0 {
+		sort.Slice(kept, func(i, j int) bool { return kept[i].ModTime().Before(kept[j].ModTime()) })
+
+		var total int64
+		for _, fi := range kept {
+			total += fi.Size()
+		}
+		for _, fi := range kept {
+			if total <= maxSizeBytes {
+				break
+			}
+			if err := os.Remove(filepath.Join(d.dir, fi.Name())); err != nil {
+				return pruned, err
+			}
+			total -= fi.Size()
+			pruned++
+		}
+	}
+
+	return pruned, nil
+}
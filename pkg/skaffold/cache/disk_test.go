@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func newTestDiskBackend(t *testing.T) (*diskBackend, string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "skaffold-cache-disk-test")
+	testutil.CheckError(t, false, err)
+
+	backend, err := newDiskBackend(dir)
+	testutil.CheckError(t, false, err)
+
+	return backend.(*diskBackend), dir, func() { os.RemoveAll(dir) }
+}
+
+func TestDiskBackendGetPut(t *testing.T) {
+	backend, _, cleanup := newTestDiskBackend(t)
+	defer cleanup()
+
+	if _, found, err := backend.Get("missing"); err != nil || found {
+		t.Fatalf("expected a miss for an unwritten key, got found=%v err=%v", found, err)
+	}
+
+	testutil.CheckError(t, false, backend.Put("key", []byte("value")))
+
+	entry, found, err := backend.Get("key")
+	testutil.CheckError(t, false, err)
+	if !found {
+		t.Fatal("expected to find the key that was just Put")
+	}
+	testutil.CheckDeepEqual(t, []byte("value"), entry.Value)
+}
+
+func TestDiskBackendPruneByAge(t *testing.T) {
+	backend, dir, cleanup := newTestDiskBackend(t)
+	defer cleanup()
+
+	testutil.CheckError(t, false, backend.Put("old", []byte("value")))
+	testutil.CheckError(t, false, backend.Put("new", []byte("value")))
+
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, filepath.Base(backend.path("old"))), old, old); err != nil {
+		t.Fatalf("setting mtime: %v", err)
+	}
+
+	pruned, err := backend.Prune(time.Hour, 0)
+	testutil.CheckError(t, false, err)
+	testutil.CheckDeepEqual(t, 1, pruned)
+
+	if _, found, _ := backend.Get("old"); found {
+		t.Error("expected the old entry to have been pruned")
+	}
+	if _, found, _ := backend.Get("new"); !found {
+		t.Error("expected the new entry to still be present")
+	}
+}
+
+func TestDiskBackendPruneBySize(t *testing.T) {
+	backend, _, cleanup := newTestDiskBackend(t)
+	defer cleanup()
+
+	testutil.CheckError(t, false, backend.Put("first", []byte("aaaaaaaaaa")))
+	time.Sleep(10 * time.Millisecond)
+	testutil.CheckError(t, false, backend.Put("second", []byte("bbbbbbbbbb")))
+
+	pruned, err := backend.Prune(0, 10)
+	testutil.CheckError(t, false, err)
+	testutil.CheckDeepEqual(t, 1, pruned)
+
+	if _, found, _ := backend.Get("first"); found {
+		t.Error("expected the oldest entry to have been pruned to stay under maxSizeBytes")
+	}
+	if _, found, _ := backend.Get("second"); !found {
+		t.Error("expected the newest entry to still be present")
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/gofrs/flock"
+	"github.com/pkg/errors"
+)
+
+// lockDir holds the advisory lock files GetOrCreate uses to keep
+// concurrent `skaffold build` invocations from racing on the same cache
+// key.
+func lockDir() (string, error) {
+	dir := filepath.Join(os.TempDir(), "skaffold-cache-locks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// lockKey takes an exclusive, cross-process lock on lockID/key and returns
+// a function to release it. lockID must identify the backend's resolved
+// storage location (see Backend.LockID), not just a cache's logical name,
+// so that two caches configured against different locations never
+// serialize on the same lock file.
+func lockKey(lockID, key string) (func(), error) {
+	dir, err := lockDir()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(lockID + "/" + key))
+	path := filepath.Join(dir, hex.EncodeToString(sum[:])+".lock")
+
+	fl := flock.New(path)
+	if err := fl.Lock(); err != nil {
+		return nil, errors.Wrapf(err, "locking %s", path)
+	}
+	return func() {
+		fl.Unlock()
+	}, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryBackend keeps entries in process memory. It doesn't survive
+// between skaffold invocations, so it's mainly useful for caches that are
+// only meaningful within a single `skaffold dev` session.
+type memoryBackend struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+func newMemoryBackend() Backend {
+	return &memoryBackend{entries: map[string]Entry{}}
+}
+
+// LockID is unique per memoryBackend instance: an in-process cache is
+// never shared across skaffold invocations, so there's no other process
+// it could meaningfully contend with.
+func (m *memoryBackend) LockID() string {
+	return fmt.Sprintf("memory:%p", m)
+}
+
+func (m *memoryBackend) Get(key string) (Entry, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	return e, ok, nil
+}
+
+func (m *memoryBackend) Put(key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = Entry{Key: key, Value: value, StoredAt: time.Now()}
+	return nil
+}
+
+func (m *memoryBackend) Prune(maxAge time.Duration, maxSizeBytes int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var pruned int
+	var kept []Entry
+	var total int64
+	for k, e := range m.entries {
+		if maxAge > 0 && now.Sub(e.StoredAt) > maxAge {
+			delete(m.entries, k)
+			pruned++
+			continue
+		}
+		kept = append(kept, e)
+		total += int64(len(e.Value))
+	}
+
+	if maxSizeBytes > 0 {
+		sort.Slice(kept, func(i, j int) bool { return kept[i].StoredAt.Before(kept[j].StoredAt) })
+		for _, e := range kept {
+			if total <= maxSizeBytes {
+				break
+			}
+			delete(m.entries, e.Key)
+			total -= int64(len(e.Value))
+			pruned++
+		}
+	}
+	return pruned, nil
+}
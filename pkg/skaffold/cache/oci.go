@@ -0,0 +1,131 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+)
+
+// storedAtAnnotation is the image config label an oci-backed entry records
+// its creation time under, since a pulled manifest otherwise carries no
+// usable creation timestamp to expire maxAge against.
+const storedAtAnnotation = "dev.skaffold.cache.storedAt"
+
+// ociBackend stores each entry as a one-layer image pushed to repo, tagged
+// by a hash of the key. It has no notion of age on its own for eviction:
+// Prune relies on the registry's own garbage collection, so it's a no-op
+// here. Age-based expiry on read is still honored, via storedAtAnnotation.
+type ociBackend struct {
+	repo string
+}
+
+func newOCIBackend(repo string) Backend {
+	return &ociBackend{repo: repo}
+}
+
+// LockID is the resolved repo, so two oci backends pointed at different
+// repos never share a lock just because they're both named the same.
+func (o *ociBackend) LockID() string {
+	return "oci:" + o.repo
+}
+
+func (o *ociBackend) tag(key string) (name.Tag, error) {
+	sum := sha256.Sum256([]byte(key))
+	return name.NewTag(fmt.Sprintf("%s:cache-%s", o.repo, hex.EncodeToString(sum[:])), name.WeakValidation)
+}
+
+func (o *ociBackend) Get(key string) (Entry, bool, error) {
+	ref, err := o.tag(key)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	img, err := remote.Image(ref, remote.WithAuthFromKeychain(docker.Keychain))
+	if err != nil {
+		// Treat any fetch failure as a cache miss: the tag may simply not
+		// exist yet.
+		return Entry{}, false, nil
+	}
+
+	layers, err := img.Layers()
+	if err != nil || len(layers) == 0 {
+		return Entry{}, false, err
+	}
+
+	rc, err := layers[0].Uncompressed()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	defer rc.Close()
+
+	value, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	storedAt := time.Now()
+	if cfg, err := img.ConfigFile(); err == nil {
+		if s, ok := cfg.Config.Labels[storedAtAnnotation]; ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				storedAt = t
+			}
+		}
+	}
+
+	return Entry{Key: key, Value: value, StoredAt: storedAt}, true, nil
+}
+
+func (o *ociBackend) Put(key string, value []byte) error {
+	ref, err := o.tag(key)
+	if err != nil {
+		return err
+	}
+
+	layer := static.NewLayer(value, "application/vnd.skaffold.cache.layer.v1")
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	if err != nil {
+		return err
+	}
+
+	img, err = mutate.Config(img, v1.Config{
+		Labels: map[string]string{storedAtAnnotation: time.Now().UTC().Format(time.RFC3339)},
+	})
+	if err != nil {
+		return err
+	}
+
+	return remote.Write(ref, img, remote.WithAuthFromKeychain(docker.Keychain))
+}
+
+func (o *ociBackend) Prune(maxAge time.Duration, maxSizeBytes int64) (int, error) {
+	// Eviction for the OCI backend is left to the registry's own retention
+	// policy; skaffold only ever writes new tags.
+	return 0, nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest"
+)
+
+// ArtifactsCacheName is the name of the built-in cache that
+// pkg/skaffold/build/cache uses by default, preserving today's behavior
+// for users who haven't declared any caches of their own.
+const ArtifactsCacheName = "artifacts"
+
+// Caches is the set of named caches declared for a run, built from the
+// `caches:` section of skaffold.yaml plus the built-in defaults. The
+// schema's Pipeline type doesn't live in this checkout yet, so callers
+// currently pass in the already-resolved map (see
+// config.SkaffoldOptions.Caches) rather than a parsed Pipeline.
+type Caches struct {
+	byName map[string]*Cache
+}
+
+// NewCaches builds the named caches declared in cfg, falling back to a
+// disk-backed "artifacts" cache if the user didn't declare one.
+func NewCaches(cfg map[string]latest.CacheConfig) (*Caches, error) {
+	if cfg == nil {
+		cfg = map[string]latest.CacheConfig{}
+	}
+	if _, ok := cfg[ArtifactsCacheName]; !ok {
+		cfg[ArtifactsCacheName] = latest.CacheConfig{}
+	}
+
+	byName := make(map[string]*Cache, len(cfg))
+	for name, c := range cfg {
+		backend, err := newBackend(name, c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "configuring cache %q", name)
+		}
+
+		maxAge, err := parseMaxAge(c.MaxAge)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cache %q: invalid maxAge", name)
+		}
+
+		byName[name] = &Cache{
+			id:      name,
+			backend: backend,
+			maxAge:  maxAge,
+			maxSize: c.MaxSizeBytes,
+		}
+	}
+	return &Caches{byName: byName}, nil
+}
+
+// Get returns the named cache, or an error if it wasn't declared.
+func (cs *Caches) Get(name string) (*Cache, error) {
+	c, ok := cs.byName[name]
+	if !ok {
+		return nil, errors.Errorf("no cache named %q configured", name)
+	}
+	return c, nil
+}
+
+// PruneAll prunes every configured cache, returning the total number of
+// entries evicted. It collects errors from individual caches rather than
+// stopping at the first one, so `skaffold cache prune` does as much work
+// as it can in one pass.
+func (cs *Caches) PruneAll() (int, error) {
+	var total int
+	var errs []string
+	for name, c := range cs.byName {
+		n, err := c.Prune()
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "pruning cache %q", name).Error())
+			continue
+		}
+		total += n
+	}
+	if len(errs) > 0 {
+		return total, errors.New(joinErrs(errs))
+	}
+	return total, nil
+}
+
+func joinErrs(errs []string) string {
+	out := errs[0]
+	for _, e := range errs[1:] {
+		out += "; " + e
+	}
+	return out
+}
+
+func parseMaxAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
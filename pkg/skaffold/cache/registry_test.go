@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestNewCachesDefaultsArtifacts(t *testing.T) {
+	caches, err := NewCaches(nil)
+	testutil.CheckError(t, false, err)
+
+	c, err := caches.Get(ArtifactsCacheName)
+	testutil.CheckError(t, false, err)
+	if c == nil {
+		t.Fatal("expected a default \"artifacts\" cache to be configured")
+	}
+}
+
+func TestCachesGetUnknown(t *testing.T) {
+	caches, err := NewCaches(nil)
+	testutil.CheckError(t, false, err)
+
+	if _, err := caches.Get("doesNotExist"); err == nil {
+		t.Fatal("expected an error for a cache that was never declared")
+	}
+}
+
+func TestNewCachesUnknownBackend(t *testing.T) {
+	_, err := NewCaches(map[string]latest.CacheConfig{
+		"bogus": {Backend: "bogus"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+}
+
+func TestPruneAllAggregates(t *testing.T) {
+	caches, err := NewCaches(map[string]latest.CacheConfig{
+		"a": {Backend: "memory", MaxAge: "1ns"},
+		"b": {Backend: "memory", MaxAge: "1ns"},
+	})
+	testutil.CheckError(t, false, err)
+
+	a, err := caches.Get("a")
+	testutil.CheckError(t, false, err)
+	testutil.CheckError(t, false, a.Put("key", []byte("value")))
+
+	b, err := caches.Get("b")
+	testutil.CheckError(t, false, err)
+	testutil.CheckError(t, false, b.Put("key", []byte("value")))
+
+	total, err := caches.PruneAll()
+	testutil.CheckError(t, false, err)
+	testutil.CheckDeepEqual(t, 2, total)
+}
@@ -18,36 +18,44 @@ package config
 
 import (
 	"strings"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest"
 )
 
 // SkaffoldOptions are options that are set by command line arguments not included
 // in the config file itself
 type SkaffoldOptions struct {
-	ConfigurationFile  string
-	Cleanup            bool
-	Notification       bool
-	Tail               bool
-	TailDev            bool
-	PortForward        bool
-	SkipTests          bool
-	CacheArtifacts     bool
-	EnableRPC          bool
-	Force              bool
-	NoPrune            bool
-	NoPruneChildren    bool
-	CustomTag          string
-	Namespace          string
-	CacheFile          string
-	Trigger            string
-	WatchPollInterval  int
-	DefaultRepo        string
-	CustomLabels       []string
-	TargetImages       []string
-	Profiles           []string
-	InsecureRegistries []string
-	Command            string
-	RPCPort            int
-	RPCHTTPPort        int
+	ConfigurationFile     string
+	Cleanup               bool
+	Notification          bool
+	Tail                  bool
+	TailDev               bool
+	PortForward           bool
+	SkipTests             bool
+	CacheArtifacts        bool
+	EnableRPC             bool
+	Force                 bool
+	NoPrune               bool
+	NoPruneChildren       bool
+	CustomTag             string
+	Namespace             string
+	CacheFile             string
+	Caches                map[string]latest.CacheConfig
+	RemoteCache           bool
+	RemoteCacheRepo       string
+	CacheHashAlgorithm    string
+	CacheHashSalt         string
+	BuildContainerOptions string
+	Trigger               string
+	WatchPollInterval     int
+	DefaultRepo           string
+	CustomLabels          []string
+	TargetImages          []string
+	Profiles              []string
+	InsecureRegistries    []string
+	Command               string
+	RPCPort               int
+	RPCHTTPPort           int
 }
 
 // Labels returns a map of labels to be applied to all deployed
@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latest
+
+// CacheConfig configures one named cache, e.g. the built-in "artifacts"
+// cache or a user-declared one such as "dependencyResolution" or
+// "testResults".
+type CacheConfig struct {
+	// Backend is the storage backend for this cache. One of "disk" (default),
+	// "memory" or "oci".
+	Backend string `yaml:"backend,omitempty"`
+
+	// Dir is the directory entries are stored under, for the "disk" backend.
+	// Defaults to `$HOME/.skaffold/cache/<name>`.
+	Dir string `yaml:"dir,omitempty"`
+
+	// MaxAge entries older than this are treated as missing on read and are
+	// evicted by `skaffold cache prune`. A Go duration string, e.g. "168h".
+	// Empty means entries never expire by age. For the "oci" backend,
+	// `skaffold cache prune` evicts nothing.
+	MaxAge string `yaml:"maxAge,omitempty"`
+
+	// MaxSizeBytes is the maximum total size of the cache. Once exceeded,
+	// `skaffold cache prune` evicts the oldest entries first. Zero means
+	// unbounded. The "oci" backend ignores it.
+	MaxSizeBytes int64 `yaml:"maxSizeBytes,omitempty"`
+
+	// Repo is the OCI repository entries are pushed to, for the "oci" backend.
+	Repo string `yaml:"repo,omitempty"`
+}